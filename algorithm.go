@@ -0,0 +1,198 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// slidingHits tracks a weighted two-window approximation of a sliding
+// window counter for a single telegram id.
+type slidingHits struct {
+	id          int64
+	windowStart int64
+	current     int32
+	prev        int32
+}
+
+// slidingMap is a fixed-capacity circular buffer of slidingHits, mirroring
+// expiryMap's eviction strategy so the "sliding" algorithm keeps the same
+// memory bound as the default one.
+type slidingMap struct {
+	mu sync.Mutex
+	// max hit table cap
+	cap int
+	// map telegram id to the index in the `hits` slice
+	idxs map[int64]int
+	// circular queue keeping per-id sliding window state
+	hits []slidingHits
+	// starting index and the size of the `hits` circular array
+	head, size int
+}
+
+func newSlidingMap(capacity int) *slidingMap {
+	return &slidingMap{
+		cap:  capacity,
+		idxs: make(map[int64]int, capacity),
+		hits: make([]slidingHits, capacity),
+	}
+}
+
+// incNGet records a hit for id and returns the effective hit count:
+// prev*(1 - elapsed/window) + current.
+func (s *slidingMap) incNGet(id int64, window int64) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC().Unix()
+
+	idx, ok := s.idxs[id]
+	if !ok {
+		s.insert(slidingHits{id: id, windowStart: now, current: 1})
+		return 1
+	}
+
+	h := &s.hits[idx]
+	elapsed := now - h.windowStart
+	if elapsed >= window {
+		windowsPassed := elapsed / window
+		if windowsPassed == 1 {
+			h.prev = h.current
+		} else {
+			h.prev = 0
+		}
+		h.current = 0
+		h.windowStart += windowsPassed * window
+		elapsed = now - h.windowStart
+	}
+
+	h.current++
+
+	weight := 1 - float64(elapsed)/float64(window)
+	if weight < 0 {
+		weight = 0
+	}
+
+	return float64(h.prev)*weight + float64(h.current)
+}
+
+func (s *slidingMap) full() bool {
+	return s.size == s.cap
+}
+
+func (s *slidingMap) free(count int) {
+	for i := 0; i < count; i++ {
+		if s.size == 0 {
+			break
+		}
+		id := s.hits[s.head].id
+		if id != isDeletedID {
+			delete(s.idxs, id)
+			s.hits[s.head].id = isDeletedID
+		}
+		s.head = (s.head + 1) % s.cap
+		s.size--
+	}
+}
+
+func (s *slidingMap) insert(h slidingHits) {
+	if s.full() {
+		s.free(1)
+	}
+
+	idx := (s.head + s.size) % s.cap
+	s.idxs[h.id] = idx
+	s.hits[idx] = h
+	s.size++
+}
+
+// tokenBucketHits tracks token_bucket state for a single telegram id.
+type tokenBucketHits struct {
+	id         int64
+	tokens     float64
+	lastRefill int64
+}
+
+// tokenBucketMap is a fixed-capacity circular buffer of tokenBucketHits,
+// mirroring expiryMap's eviction strategy.
+type tokenBucketMap struct {
+	mu sync.Mutex
+	// max hit table cap
+	cap int
+	// map telegram id to the index in the `hits` slice
+	idxs map[int64]int
+	// circular queue keeping per-id token bucket state
+	hits []tokenBucketHits
+	// starting index and the size of the `hits` circular array
+	head, size int
+}
+
+func newTokenBucketMap(capacity int) *tokenBucketMap {
+	return &tokenBucketMap{
+		cap:  capacity,
+		idxs: make(map[int64]int, capacity),
+		hits: make([]tokenBucketHits, capacity),
+	}
+}
+
+// take refills id's bucket, then consumes one token if available.
+// It reports whether the request may proceed.
+func (t *tokenBucketMap) take(id int64, window int64, limit int32, burst int32) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().UTC().Unix()
+	rate := float64(limit) / float64(window)
+
+	idx, ok := t.idxs[id]
+	if !ok {
+		if burst < 1 {
+			t.insert(tokenBucketHits{id: id, tokens: float64(burst), lastRefill: now})
+			return false
+		}
+		t.insert(tokenBucketHits{id: id, tokens: float64(burst) - 1, lastRefill: now})
+		return true
+	}
+
+	h := &t.hits[idx]
+	elapsed := now - h.lastRefill
+	h.tokens = math.Min(float64(burst), h.tokens+float64(elapsed)*rate)
+	h.lastRefill = now
+
+	if h.tokens < 1 {
+		return false
+	}
+
+	h.tokens--
+	return true
+}
+
+func (t *tokenBucketMap) full() bool {
+	return t.size == t.cap
+}
+
+func (t *tokenBucketMap) free(count int) {
+	for i := 0; i < count; i++ {
+		if t.size == 0 {
+			break
+		}
+		id := t.hits[t.head].id
+		if id != isDeletedID {
+			delete(t.idxs, id)
+			t.hits[t.head].id = isDeletedID
+		}
+		t.head = (t.head + 1) % t.cap
+		t.size--
+	}
+}
+
+func (t *tokenBucketMap) insert(h tokenBucketHits) {
+	if t.full() {
+		t.free(1)
+	}
+
+	idx := (t.head + t.size) % t.cap
+	t.idxs[h.id] = idx
+	t.hits[idx] = h
+	t.size++
+}
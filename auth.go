@@ -0,0 +1,73 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+const (
+	consoleAuthBearer = "bearer"
+	consoleAuthBasic  = "basic"
+)
+
+// validateConsoleAuth checks that auth, if set, is fully and unambiguously
+// configured. nil is valid and means the management server requires no
+// authentication.
+func validateConsoleAuth(auth *ConsoleAuthConfig) error {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Mode {
+	case consoleAuthBearer:
+		if auth.Token == "" {
+			return ErrInvalidConsoleAuth
+		}
+	case consoleAuthBasic:
+		if auth.Username == "" || auth.Password == "" {
+			return ErrInvalidConsoleAuth
+		}
+	default:
+		return ErrInvalidConsoleAuth
+	}
+
+	return nil
+}
+
+// authMiddleware enforces r.config.ConsoleAuth, if set, on every management
+// request. Credential comparisons use subtle.ConstantTimeCompare so a
+// mistyped token does not leak timing information about the correct one.
+func (r *rateLimiter) authMiddleware(next http.Handler) http.Handler {
+	auth := r.config.ConsoleAuth
+	if auth == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch auth.Mode {
+		case consoleAuthBearer:
+			token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if token == req.Header.Get("Authorization") || !constantTimeEqual(token, auth.Token) {
+				rw.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(rw, "401 unauthorized", http.StatusUnauthorized)
+				return
+			}
+		case consoleAuthBasic:
+			username, password, ok := req.BasicAuth()
+			if !ok || !constantTimeEqual(username, auth.Username) || !constantTimeEqual(password, auth.Password) {
+				rw.Header().Set("WWW-Authenticate", `Basic realm="traefik-telegram-ratelimiter"`)
+				http.Error(rw, "401 unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// constantTimeEqual reports whether a and b are equal, in time independent
+// of where they first differ.
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,119 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// bodyBufferPool recycles the in-memory buffers used to capture request
+// bodies while extractTgID scans them, so steady-state traffic does not
+// allocate a fresh buffer per request.
+var bodyBufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// defaultSpillThresholdBytes bounds how much of a request body spillBuffer
+// keeps in memory before spooling the rest to a temp file. It is deliberately
+// well below MaxBodyBytes: the latter is the hard per-request reject cap
+// enforced by http.MaxBytesReader, while this is the point past which a
+// single large-but-still-allowed body stops being worth holding in RAM.
+const defaultSpillThresholdBytes = 64 * 1024 // 64 KiB
+
+// spillBuffer is an io.Writer that captures up to max bytes in a pooled
+// bytes.Buffer and, once that limit is exceeded, spills the rest to a temp
+// file. It lets ServeHTTP retain the full request body for the downstream
+// handler without holding oversized payloads in memory.
+type spillBuffer struct {
+	max  int64
+	buf  *bytes.Buffer
+	file *os.File
+}
+
+func newSpillBuffer(max int64) *spillBuffer {
+	buf := bodyBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return &spillBuffer{max: max, buf: buf}
+}
+
+func (s *spillBuffer) Write(p []byte) (int, error) {
+	if s.file == nil {
+		if int64(s.buf.Len()+len(p)) <= s.max {
+			return s.buf.Write(p)
+		}
+
+		f, err := os.CreateTemp("", "tgratelimiter-body-*")
+		if err != nil {
+			return 0, err
+		}
+		if _, err := f.Write(s.buf.Bytes()); err != nil {
+			f.Close()
+			os.Remove(f.Name())
+			return 0, err
+		}
+
+		s.buf.Reset()
+		bodyBufferPool.Put(s.buf)
+		s.buf = nil
+		s.file = f
+	}
+
+	return s.file.Write(p)
+}
+
+// body returns a fresh reader over the captured request body. Its Close
+// releases the pooled buffer or removes the temp file, whichever was used.
+func (s *spillBuffer) body() (io.ReadCloser, error) {
+	if s.file != nil {
+		if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return &spilledFileBody{f: s.file}, nil
+	}
+
+	data := append([]byte(nil), s.buf.Bytes()...)
+	s.buf.Reset()
+	bodyBufferPool.Put(s.buf)
+	s.buf = nil
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// discard releases s's captured bytes without handing them to a reader, for
+// callers that reject the request before the downstream handler ever sees
+// the body.
+func (s *spillBuffer) discard() {
+	if s.file != nil {
+		name := s.file.Name()
+		s.file.Close()
+		os.Remove(name)
+		s.file = nil
+		return
+	}
+
+	if s.buf != nil {
+		s.buf.Reset()
+		bodyBufferPool.Put(s.buf)
+		s.buf = nil
+	}
+}
+
+// spilledFileBody wraps a temp file holding an oversized request body,
+// removing it once the downstream handler is done reading.
+type spilledFileBody struct {
+	f *os.File
+}
+
+func (b *spilledFileBody) Read(p []byte) (int, error) {
+	return b.f.Read(p)
+}
+
+func (b *spilledFileBody) Close() error {
+	name := b.f.Name()
+	err := b.f.Close()
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpillBuffer_SpillsBeforeHittingMax(t *testing.T) {
+	// The spill threshold must be lower than the hard reject cap, or the
+	// temp-file path can never trigger for a body the reader actually
+	// allows through.
+	const max = 1 << 20 // matches MaxBodyBytes in ServeHTTP
+	threshold := int64(8)
+	if threshold >= max {
+		t.Fatal("spill threshold must stay below the hard reject cap")
+	}
+
+	sb := newSpillBuffer(threshold)
+	payload := []byte(strings.Repeat("x", int(threshold)+1))
+	if _, err := sb.Write(payload); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	if sb.file == nil {
+		t.Fatal("expected spillBuffer to spool to a temp file once threshold is exceeded")
+	}
+
+	body, err := sb.body()
+	if err != nil {
+		t.Fatalf("unexpected error from body(): %v", err)
+	}
+	defer body.Close()
+
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("got %q, want %q", got, payload)
+	}
+}
+
+func TestSpillBuffer_StaysInMemoryUnderThreshold(t *testing.T) {
+	sb := newSpillBuffer(64)
+	payload := []byte("small body")
+	if _, err := sb.Write(payload); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if sb.file != nil {
+		t.Fatal("expected spillBuffer to stay in memory under threshold")
+	}
+}
+
+func TestSpillBuffer_Discard(t *testing.T) {
+	sb := newSpillBuffer(4)
+	if _, err := sb.Write([]byte("overflowing")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if sb.file == nil {
+		t.Fatal("expected a spilled temp file before discard")
+	}
+
+	name := sb.file.Name()
+	sb.discard()
+
+	if sb.file != nil {
+		t.Fatal("expected discard to clear the file handle")
+	}
+	if _, err := os.Open(name); err == nil {
+		t.Fatal("expected the temp file to be removed after discard")
+	}
+}
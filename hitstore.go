@@ -0,0 +1,92 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	backendMemory = "memory"
+	backendRedis  = "redis"
+
+	defaultBackendKeyPrefix = "tgid:"
+	defaultBackendTimeoutMs = 200
+)
+
+// HitStore records and reports per-telegram-id hit counters. It is the
+// extension point that lets the rate limiter share counters across several
+// Traefik replicas instead of keeping them in a single process's memory.
+type HitStore interface {
+	// IncNGet increments and returns the hit count for id, setting its
+	// expiration to expire seconds from now if this is the first hit.
+	IncNGet(id int64, expire int64) (int32, error)
+	// Get returns the current hit count for id, or 0 if unknown/expired.
+	Get(id int64) (int32, error)
+	// Reset zeroes the hit count for id. It reports whether id was known.
+	Reset(id int64) (bool, error)
+	// List returns the hit count of every id currently tracked.
+	List() (map[int64]int32, error)
+}
+
+// newHitStore builds the HitStore configured by config.Backend.
+func newHitStore(config *Config) (HitStore, error) {
+	switch config.Backend {
+	case "", backendMemory:
+		return newMemoryHitStore(config.HitTableSize), nil
+	case backendRedis:
+		if config.BackendURL == nil || *config.BackendURL == "" {
+			return nil, ErrMissingBackendURL
+		}
+		prefix := config.BackendKeyPrefix
+		if prefix == "" {
+			prefix = defaultBackendKeyPrefix
+		}
+		timeoutMs := config.BackendTimeoutMs
+		if timeoutMs <= 0 {
+			timeoutMs = defaultBackendTimeoutMs
+		}
+		return newRedisHitStore(*config.BackendURL, prefix, time.Duration(timeoutMs)*time.Millisecond)
+	default:
+		return nil, fmt.Errorf("unknown hit store backend: %q", config.Backend)
+	}
+}
+
+// validateBackendAlgorithm rejects combinations where algorithm keeps its
+// state in-process (sliding, token_bucket) while backend is meant to share
+// hit counters across replicas (redis). Only "fixed" currently goes through
+// HitStore, so pairing it with redis is the only combination that actually
+// behaves as configured.
+func validateBackendAlgorithm(backend, algorithm string) error {
+	if backend == backendRedis && algorithm != "" && algorithm != algorithmFixed {
+		return ErrBackendAlgorithmMismatch
+	}
+	return nil
+}
+
+// memoryHitStore adapts the in-process expiryMap to the HitStore interface.
+type memoryHitStore struct {
+	m *expiryMap
+}
+
+func newMemoryHitStore(capacity int) *memoryHitStore {
+	return &memoryHitStore{m: newExpiryMap(capacity)}
+}
+
+func (s *memoryHitStore) IncNGet(id int64, expire int64) (int32, error) {
+	return s.m.incNGet(id, expire), nil
+}
+
+func (s *memoryHitStore) Get(id int64) (int32, error) {
+	return s.m.get(id), nil
+}
+
+func (s *memoryHitStore) Reset(id int64) (bool, error) {
+	return s.m.reset(id), nil
+}
+
+func (s *memoryHitStore) List() (map[int64]int32, error) {
+	m := s.m.list()
+	// drop queue slots whose id mapping was already deleted
+	delete(m, isDeletedID)
+	return m, nil
+}
@@ -0,0 +1,32 @@
+package traefik_telegram_ratelimiter
+
+import "testing"
+
+func TestValidateBackendAlgorithm(t *testing.T) {
+	cases := []struct {
+		name      string
+		backend   string
+		algorithm string
+		wantErr   bool
+	}{
+		{"memory with fixed", backendMemory, algorithmFixed, false},
+		{"memory with sliding", backendMemory, algorithmSliding, false},
+		{"memory with token_bucket", backendMemory, algorithmTokenBucket, false},
+		{"redis with fixed", backendRedis, algorithmFixed, false},
+		{"redis with empty algorithm", backendRedis, "", false},
+		{"redis with sliding", backendRedis, algorithmSliding, true},
+		{"redis with token_bucket", backendRedis, algorithmTokenBucket, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateBackendAlgorithm(c.backend, c.algorithm)
+			if c.wantErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package traefik_telegram_ratelimiter
+
+import "strings"
+
+// builtinIDPaths cover every telegram Update variant that carries a sender
+// id, mapped to the object that holds it. poll_answer has no "from" field,
+// so it falls back to "user".
+var builtinIDPaths = [][]string{
+	{"message", "from", "id"},
+	{"edited_message", "from", "id"},
+	{"channel_post", "from", "id"},
+	{"edited_channel_post", "from", "id"},
+	{"inline_query", "from", "id"},
+	{"chosen_inline_result", "from", "id"},
+	{"callback_query", "from", "id"},
+	{"shipping_query", "from", "id"},
+	{"pre_checkout_query", "from", "id"},
+	{"poll_answer", "user", "id"},
+	{"my_chat_member", "from", "id"},
+	{"chat_member", "from", "id"},
+	{"chat_join_request", "from", "id"},
+	{"business_message", "from", "id"},
+	{"edited_business_message", "from", "id"},
+	{"message_reaction", "from", "id"},
+	{"message_reaction_count", "from", "id"},
+}
+
+// idPathNode is a node in a trie of id paths: extractTgID walks it alongside
+// the incoming JSON object, one key per level, returning the first path
+// whose leaf is reached.
+type idPathNode struct {
+	leaf     bool
+	children map[string]*idPathNode
+}
+
+func newIDPathNode() *idPathNode {
+	return &idPathNode{children: make(map[string]*idPathNode)}
+}
+
+// buildIDPathTrie merges custom JSON-pointer-style paths with the built-in
+// telegram Update variants into a single trie. Insertion order does not
+// affect matching: extractTgID returns whichever path it reaches first in
+// the document, custom or built-in.
+func buildIDPathTrie(customPaths []string) *idPathNode {
+	root := newIDPathNode()
+
+	for _, p := range customPaths {
+		if segments := splitIDPath(p); len(segments) > 0 {
+			root.insert(segments)
+		}
+	}
+	for _, segments := range builtinIDPaths {
+		root.insert(segments)
+	}
+
+	return root
+}
+
+func (n *idPathNode) insert(segments []string) {
+	node := n
+	for i, seg := range segments {
+		child, ok := node.children[seg]
+		if !ok {
+			child = newIDPathNode()
+			node.children[seg] = child
+		}
+		if i == len(segments)-1 {
+			child.leaf = true
+		}
+		node = child
+	}
+}
+
+// splitIDPath turns a JSON-pointer-style path such as "/message/from/id"
+// into its segments. Leading/trailing slashes and empty segments are
+// ignored.
+func splitIDPath(path string) []string {
+	var segments []string
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
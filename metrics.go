@@ -0,0 +1,168 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// requestOutcome labels telegram_ratelimit_requests_total.
+type requestOutcome string
+
+const (
+	outcomeAllowed           requestOutcome = "allowed"
+	outcomeRejectedLimit     requestOutcome = "rejected_limit"
+	outcomeRejectedWlLimit   requestOutcome = "rejected_wl_limit"
+	outcomeRejectedBlacklist requestOutcome = "rejected_blacklist"
+	outcomeExtractError      requestOutcome = "extract_error"
+)
+
+// reloadResult labels telegram_ratelimit_list_reload_total.
+type reloadResult string
+
+const (
+	reloadSuccess reloadResult = "success"
+	reloadFailure reloadResult = "error"
+)
+
+// extractDurationBuckets are the histogram boundaries, in seconds, for
+// telegram_ratelimit_extract_duration_seconds.
+var extractDurationBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// metrics is a small hand-rolled Prometheus registry. Traefik plugins run
+// under Yaegi, which cannot load prometheus/client_golang, so counters and
+// gauges are plain sync/atomic fields rendered directly in the text
+// exposition format.
+type metrics struct {
+	requestsAllowed           int64
+	requestsRejectedLimit     int64
+	requestsRejectedWlLimit   int64
+	requestsRejectedBlacklist int64
+	requestsExtractError      int64
+
+	listReloadSuccess int64
+	listReloadFailure int64
+
+	hitTableSize  int64
+	hitTableCap   int64
+	whitelistSize int64
+	blacklistSize int64
+
+	extractDuration *histogram
+}
+
+func newMetrics() *metrics {
+	return &metrics{extractDuration: newHistogram(extractDurationBuckets)}
+}
+
+func (m *metrics) observeRequest(o requestOutcome) {
+	switch o {
+	case outcomeAllowed:
+		atomic.AddInt64(&m.requestsAllowed, 1)
+	case outcomeRejectedLimit:
+		atomic.AddInt64(&m.requestsRejectedLimit, 1)
+	case outcomeRejectedWlLimit:
+		atomic.AddInt64(&m.requestsRejectedWlLimit, 1)
+	case outcomeRejectedBlacklist:
+		atomic.AddInt64(&m.requestsRejectedBlacklist, 1)
+	case outcomeExtractError:
+		atomic.AddInt64(&m.requestsExtractError, 1)
+	}
+}
+
+func (m *metrics) observeReload(r reloadResult) {
+	if r == reloadSuccess {
+		atomic.AddInt64(&m.listReloadSuccess, 1)
+	} else {
+		atomic.AddInt64(&m.listReloadFailure, 1)
+	}
+}
+
+func (m *metrics) setHitTableSize(n int)  { atomic.StoreInt64(&m.hitTableSize, int64(n)) }
+func (m *metrics) setHitTableCap(n int)   { atomic.StoreInt64(&m.hitTableCap, int64(n)) }
+func (m *metrics) setWhitelistSize(n int) { atomic.StoreInt64(&m.whitelistSize, int64(n)) }
+func (m *metrics) setBlacklistSize(n int) { atomic.StoreInt64(&m.blacklistSize, int64(n)) }
+
+// write renders every metric in the Prometheus text exposition format.
+func (m *metrics) write(w io.Writer) {
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_requests_total Requests processed, by outcome")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_requests_total counter")
+	fmt.Fprintf(w, "telegram_ratelimit_requests_total{outcome=\"allowed\"} %d\n", atomic.LoadInt64(&m.requestsAllowed))
+	fmt.Fprintf(w, "telegram_ratelimit_requests_total{outcome=\"rejected_limit\"} %d\n", atomic.LoadInt64(&m.requestsRejectedLimit))
+	fmt.Fprintf(w, "telegram_ratelimit_requests_total{outcome=\"rejected_wl_limit\"} %d\n", atomic.LoadInt64(&m.requestsRejectedWlLimit))
+	fmt.Fprintf(w, "telegram_ratelimit_requests_total{outcome=\"rejected_blacklist\"} %d\n", atomic.LoadInt64(&m.requestsRejectedBlacklist))
+	fmt.Fprintf(w, "telegram_ratelimit_requests_total{outcome=\"extract_error\"} %d\n", atomic.LoadInt64(&m.requestsExtractError))
+
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_hit_table_size Number of ids currently tracked by the hit store")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_hit_table_size gauge")
+	fmt.Fprintf(w, "telegram_ratelimit_hit_table_size %d\n", atomic.LoadInt64(&m.hitTableSize))
+
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_hit_table_cap Configured capacity of the hit store")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_hit_table_cap gauge")
+	fmt.Fprintf(w, "telegram_ratelimit_hit_table_cap %d\n", atomic.LoadInt64(&m.hitTableCap))
+
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_whitelist_size Number of ids in the whitelist")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_whitelist_size gauge")
+	fmt.Fprintf(w, "telegram_ratelimit_whitelist_size %d\n", atomic.LoadInt64(&m.whitelistSize))
+
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_blacklist_size Number of ids in the blacklist")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_blacklist_size gauge")
+	fmt.Fprintf(w, "telegram_ratelimit_blacklist_size %d\n", atomic.LoadInt64(&m.blacklistSize))
+
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_list_reload_total Whitelist/blacklist reload attempts, by result")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_list_reload_total counter")
+	fmt.Fprintf(w, "telegram_ratelimit_list_reload_total{result=\"success\"} %d\n", atomic.LoadInt64(&m.listReloadSuccess))
+	fmt.Fprintf(w, "telegram_ratelimit_list_reload_total{result=\"error\"} %d\n", atomic.LoadInt64(&m.listReloadFailure))
+
+	fmt.Fprintln(w, "# HELP telegram_ratelimit_extract_duration_seconds Time spent extracting a telegram id from the request body")
+	fmt.Fprintln(w, "# TYPE telegram_ratelimit_extract_duration_seconds histogram")
+	m.extractDuration.write(w, "telegram_ratelimit_extract_duration_seconds")
+}
+
+// histogram is a minimal cumulative histogram, sufficient for a single
+// Prometheus metric without pulling in a client library.
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64 // upper bounds, ascending
+	counts  []int64   // counts[i] = observations <= buckets[i], cumulative
+	sum     float64
+	count   int64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &histogram{buckets: b, counts: make([]int64, len(b))}
+}
+
+func (h *histogram) observe(seconds float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sum += seconds
+	h.count++
+	for i, upperBound := range h.buckets {
+		if seconds <= upperBound {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *histogram) write(w io.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, upperBound := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(upperBound), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,286 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// incrExpireScript atomically increments a counter and, only on its first
+// hit, sets its TTL - equivalent to INCR followed by EXPIRE NX.
+const incrExpireScript = `local v = redis.call('INCR', KEYS[1])
+if v == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[1])
+end
+return v`
+
+// redisHitStore is a HitStore backed by redis, so multiple Traefik replicas
+// can share the same hit counters. It speaks just enough RESP over a plain
+// TCP connection to avoid depending on a redis client library, since
+// Traefik's Yaegi plugin sandbox only supports the standard library.
+type redisHitStore struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newRedisHitStore(rawURL, prefix string, timeout time.Duration) (*redisHitStore, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis backend url: %w", err)
+	}
+
+	password := ""
+	if u.User != nil {
+		password, _ = u.User.Password()
+	}
+
+	db := 0
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		db, err = strconv.Atoi(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis db in backend url: %w", err)
+		}
+	}
+
+	return &redisHitStore{
+		addr:     u.Host,
+		password: password,
+		db:       db,
+		prefix:   prefix,
+		timeout:  timeout,
+	}, nil
+}
+
+func (s *redisHitStore) key(id int64) string {
+	return s.prefix + strconv.FormatInt(id, 10)
+}
+
+func (s *redisHitStore) IncNGet(id int64, expire int64) (int32, error) {
+	reply, err := s.exec("EVAL", incrExpireScript, "1", s.key(id), strconv.FormatInt(expire, 10))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reply to hit script: %#v", reply)
+	}
+	return int32(n), nil
+}
+
+func (s *redisHitStore) Get(id int64) (int32, error) {
+	reply, err := s.exec("GET", s.key(id))
+	if err != nil {
+		return 0, err
+	}
+	if reply == nil {
+		return 0, nil
+	}
+	str, ok := reply.(string)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reply to GET: %#v", reply)
+	}
+	n, err := strconv.ParseInt(str, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int32(n), nil
+}
+
+func (s *redisHitStore) Reset(id int64) (bool, error) {
+	reply, err := s.exec("DEL", s.key(id))
+	if err != nil {
+		return false, err
+	}
+	n, _ := reply.(int64)
+	return n > 0, nil
+}
+
+func (s *redisHitStore) List() (map[int64]int32, error) {
+	result := make(map[int64]int32)
+	cursor := "0"
+	pattern := s.prefix + "*"
+
+	for {
+		reply, err := s.exec("SCAN", cursor, "MATCH", pattern, "COUNT", "1000")
+		if err != nil {
+			return nil, err
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, fmt.Errorf("redis: unexpected reply to SCAN: %#v", reply)
+		}
+		cursor, _ = page[0].(string)
+		keys, _ := page[1].([]interface{})
+
+		for _, k := range keys {
+			keyStr, _ := k.(string)
+			id, err := strconv.ParseInt(strings.TrimPrefix(keyStr, s.prefix), 10, 64)
+			if err != nil {
+				continue
+			}
+			hits, err := s.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			result[id] = hits
+		}
+
+		if cursor == "0" {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// exec sends a single redis command over the shared connection and returns
+// its parsed reply, (re)connecting as needed.
+func (s *redisHitStore) exec(args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connectLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		s.closeLocked()
+		return nil, err
+	}
+
+	if err := writeRESPCommand(s.conn, args...); err != nil {
+		s.closeLocked()
+		return nil, err
+	}
+
+	reply, err := readRESPReply(s.r)
+	if err != nil {
+		s.closeLocked()
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (s *redisHitStore) connectLocked() error {
+	conn, err := net.DialTimeout("tcp", s.addr, s.timeout)
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(conn)
+
+	if s.password != "" {
+		if err := writeRESPCommand(conn, "AUTH", s.password); err != nil {
+			conn.Close()
+			return err
+		}
+		if _, err := readRESPReply(r); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	if s.db != 0 {
+		if err := writeRESPCommand(conn, "SELECT", strconv.Itoa(s.db)); err != nil {
+			conn.Close()
+			return err
+		}
+		if _, err := readRESPReply(r); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	s.conn = conn
+	s.r = r
+	return nil
+}
+
+func (s *redisHitStore) closeLocked() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.r = nil
+	}
+}
+
+// writeRESPCommand encodes args as a RESP array of bulk strings.
+func writeRESPCommand(w io.Writer, args ...string) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(a), a)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readRESPReply parses a single RESP reply: simple string, error, integer,
+// bulk string or array (recursively). Errors decode to a Go error.
+func readRESPReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply line")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			v, err := readRESPReply(r)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = v
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply prefix %q", line[0])
+	}
+}
@@ -0,0 +1,264 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedisServer is a minimal in-process RESP server standing in for
+// miniredis: the plugin deliberately avoids a redis client dependency (see
+// redis_hitstore.go) since Traefik's Yaegi plugin sandbox only supports the
+// standard library, so its tests stay dependency-free too. It implements
+// just the commands redisHitStore issues: EVAL (the incr+expire script),
+// GET, DEL and SCAN, plus AUTH and SELECT, which it accepts unchecked.
+type fakeRedisServer struct {
+	ln net.Listener
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newFakeRedisServer(t *testing.T) *fakeRedisServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake redis server: %v", err)
+	}
+
+	s := &fakeRedisServer{ln: ln, counts: make(map[string]int64)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeRedisServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeRedisServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedisServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		reply := s.dispatch(args)
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedisServer) dispatch(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "EVAL":
+		// mirrors the INCR half of incrExpireScript; the expire argument
+		// is ignored and keys never expire here.
+		// args is [EVAL, script, numkeys, key, expire...].
+		key := args[3]
+		s.counts[key]++
+		return fmt.Sprintf(":%d\r\n", s.counts[key])
+	case "GET":
+		key := args[1]
+		v, ok := s.counts[key]
+		if !ok {
+			return "$-1\r\n"
+		}
+		str := strconv.FormatInt(v, 10)
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(str), str)
+	case "DEL":
+		key := args[1]
+		_, ok := s.counts[key]
+		delete(s.counts, key)
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SCAN":
+		pattern := ""
+		for i := 1; i < len(args)-1; i++ {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		prefix := strings.TrimSuffix(pattern, "*")
+
+		var keys []string
+		for k := range s.counts {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var b strings.Builder
+		b.WriteString("*2\r\n$1\r\n0\r\n")
+		fmt.Fprintf(&b, "*%d\r\n", len(keys))
+		for _, k := range keys {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(k), k)
+		}
+		return b.String()
+	case "SELECT", "AUTH":
+		return "+OK\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command %q\r\n", args[0])
+	}
+}
+
+// readRESPCommand reads a single RESP array-of-bulk-strings command, the
+// only shape redisHitStore ever sends.
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command line: %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := 0; i < n; i++ {
+		lenLine, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		lenLine = strings.TrimRight(lenLine, "\r\n")
+		if !strings.HasPrefix(lenLine, "$") {
+			return nil, fmt.Errorf("unexpected bulk header: %q", lenLine)
+		}
+		size, err := strconv.Atoi(lenLine[1:])
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisHitStore_IncNGetAndGet(t *testing.T) {
+	srv := newFakeRedisServer(t)
+
+	store, err := newRedisHitStore("redis://"+srv.addr()+"/0", "tgid:", 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error from newRedisHitStore: %v", err)
+	}
+
+	hits, err := store.IncNGet(42, 60)
+	if err != nil {
+		t.Fatalf("unexpected error from IncNGet: %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("got %d hits, want 1", hits)
+	}
+
+	hits, err = store.IncNGet(42, 60)
+	if err != nil {
+		t.Fatalf("unexpected error from IncNGet: %v", err)
+	}
+	if hits != 2 {
+		t.Fatalf("got %d hits, want 2", hits)
+	}
+
+	got, err := store.Get(42)
+	if err != nil {
+		t.Fatalf("unexpected error from Get: %v", err)
+	}
+	if got != 2 {
+		t.Fatalf("got %d from Get, want 2", got)
+	}
+
+	if got, err := store.Get(999); err != nil || got != 0 {
+		t.Fatalf("Get on unknown id = (%d, %v), want (0, nil)", got, err)
+	}
+}
+
+func TestRedisHitStore_Reset(t *testing.T) {
+	srv := newFakeRedisServer(t)
+	store, err := newRedisHitStore("redis://"+srv.addr()+"/0", "tgid:", 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := store.IncNGet(7, 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := store.Reset(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !found {
+		t.Fatal("expected Reset to report the id was found")
+	}
+
+	if got, err := store.Get(7); err != nil || got != 0 {
+		t.Fatalf("Get after Reset = (%d, %v), want (0, nil)", got, err)
+	}
+
+	if found, err := store.Reset(7); err != nil || found {
+		t.Fatalf("Reset on already-reset id = (%t, %v), want (false, nil)", found, err)
+	}
+}
+
+func TestRedisHitStore_List(t *testing.T) {
+	srv := newFakeRedisServer(t)
+	store, err := newRedisHitStore("redis://"+srv.addr()+"/0", "tgid:", 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []int64{1, 2, 3} {
+		if _, err := store.IncNGet(id, 60); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("unexpected error from List: %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("got %d entries, want 3", len(list))
+	}
+	for _, id := range []int64{1, 2, 3} {
+		if list[id] != 1 {
+			t.Fatalf("list[%d] = %d, want 1", id, list[id])
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// urlCache remembers the validators and parsed ids from the last successful
+// fetch of a whitelist/blacklist URL, so a scheduled reload can issue a
+// conditional GET and skip re-parsing an unchanged list.
+type urlCache struct {
+	etag         string
+	lastModified string
+	ids          map[int64]struct{}
+}
+
+// fetchIDURL conditionally re-fetches url into cache. A 304 response leaves
+// cache.ids untouched; any other non-2xx status is an error and also leaves
+// cache.ids untouched, so a failing reload cannot wipe the last-known list.
+func fetchIDURL(url string, cache *urlCache) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if cache.etag != "" {
+		req.Header.Set("If-None-Match", cache.etag)
+	}
+	if cache.lastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.lastModified)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status fetching %s: %s", url, res.Status)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	ids := make(map[int64]struct{}, len(cache.ids))
+	scanIDs(bytes.NewReader(data), ids)
+
+	cache.ids = ids
+	cache.etag = res.Header.Get("ETag")
+	cache.lastModified = res.Header.Get("Last-Modified")
+	return nil
+}
+
+// reloadStatus tracks the outcome of the most recent whitelist/blacklist
+// reload, surfaced on the management server's /reload/status route.
+type reloadStatus struct {
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+func (s *reloadStatus) recordSuccess(at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSuccess = at
+}
+
+func (s *reloadStatus) recordError(err error, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err.Error()
+	s.lastErrorAt = at
+}
+
+// reloadStatusSnapshot is a point-in-time copy of reloadStatus, safe to read
+// without holding its mutex.
+type reloadStatusSnapshot struct {
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+func (s *reloadStatus) snapshot() reloadStatusSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return reloadStatusSnapshot{
+		lastSuccess: s.lastSuccess,
+		lastError:   s.lastError,
+		lastErrorAt: s.lastErrorAt,
+	}
+}
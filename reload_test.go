@@ -0,0 +1,91 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// TestUpdateListsConcurrent exercises updateLists the way the scheduled
+// reload goroutine and a manual POST /reload can overlap in production,
+// with a whitelist file as the only source. wlCache/blCache stay nil here;
+// the URL cache path is covered by TestUpdateListsConcurrentURLCache.
+func TestUpdateListsConcurrent(t *testing.T) {
+	wl, err := os.CreateTemp("", "tgratelimiter-wl-*")
+	if err != nil {
+		t.Fatalf("failed to create temp whitelist: %v", err)
+	}
+	defer os.Remove(wl.Name())
+	if _, err := wl.WriteString("1\n2\n3\n"); err != nil {
+		t.Fatalf("failed to write temp whitelist: %v", err)
+	}
+	wl.Close()
+
+	wlPath := wl.Name()
+	r := &rateLimiter{
+		config: &Config{
+			Whitelist: &wlPath,
+		},
+		metrics:      newMetrics(),
+		reloadStatus: &reloadStatus{},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.updateLists(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.rwmu.RLock()
+	defer r.rwmu.RUnlock()
+	if len(r.whitelist) != 3 {
+		t.Fatalf("got %d whitelisted ids, want 3", len(r.whitelist))
+	}
+}
+
+// TestUpdateListsConcurrentURLCache exercises the urlCache path (ETag
+// bookkeeping) under the same concurrency. Run with -race: urlCache's
+// fields are only ever touched from inside updateLists, under reloadMu.
+func TestUpdateListsConcurrentURLCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("10\n20\n"))
+	}))
+	defer srv.Close()
+
+	url := srv.URL
+	r := &rateLimiter{
+		config: &Config{
+			WhitelistURL: &url,
+		},
+		metrics:      newMetrics(),
+		reloadStatus: &reloadStatus{},
+		wlCache:      &urlCache{},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.updateLists(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	r.rwmu.RLock()
+	defer r.rwmu.RUnlock()
+	if len(r.whitelist) != 2 {
+		t.Fatalf("got %d whitelisted ids, want 2", len(r.whitelist))
+	}
+}
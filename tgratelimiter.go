@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,12 +22,24 @@ import (
 )
 
 const defaultHitTableSize = 50000
-const defaultExpire = 86400 // 24 hours
+const defaultExpire = 86400         // 24 hours
+const defaultMaxBodyBytes = 1 << 20 // 1 MiB
 const isDeletedID int64 = -1 << 63
 
 var (
-	ErrUnknownMessageFormat = errors.New("unknown incoming telegram message format")
-	ErrInvalidHitTableSize  = errors.New("hit table size cannot be 0 or less")
+	ErrUnknownMessageFormat     = errors.New("unknown incoming telegram message format")
+	ErrInvalidHitTableSize      = errors.New("hit table size cannot be 0 or less")
+	ErrMissingBackendURL        = errors.New("backendURL is required when backend is \"redis\"")
+	ErrInvalidAlgorithm         = errors.New("unknown rate limit algorithm")
+	ErrBackendAlgorithmMismatch = errors.New("sliding and token_bucket algorithms keep their state in-process and are not yet shared across replicas; use backend \"memory\", or algorithm \"fixed\" with backend \"redis\"")
+	ErrNoTelegramID             = errors.New("telegram update parsed but no configured id path matched")
+	ErrInvalidConsoleAuth       = errors.New("consoleAuth requires mode \"bearer\" (with a token) or \"basic\" (with a username and password)")
+)
+
+const (
+	algorithmFixed       = "fixed"
+	algorithmSliding     = "sliding"
+	algorithmTokenBucket = "token_bucket"
 )
 
 var (
@@ -57,15 +70,75 @@ type Config struct {
 	Console bool `json:"console" yaml:"console" toml:"console"`
 	// management server address
 	ConsoleAddress *string `json:"consoleAddress" yaml:"consoleAddress" toml:"consoleAddress"`
+	// MaxBodyBytes caps how much of the request body is read while looking
+	// for a telegram id. Bodies larger than this are rejected instead of
+	// being buffered in full.
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" toml:"maxBodyBytes,omitempty"`
+	// Backend selects the hit counter storage: "memory" (default) or "redis".
+	Backend string `json:"backend,omitempty" yaml:"backend,omitempty" toml:"backend,omitempty"`
+	// BackendURL is the connection URL for the redis backend, e.g.
+	// redis://:password@host:6379/0
+	BackendURL *string `json:"backendURL,omitempty" yaml:"backendURL,omitempty" toml:"backendURL,omitempty"`
+	// BackendKeyPrefix prefixes every key the redis backend writes
+	BackendKeyPrefix string `json:"backendKeyPrefix,omitempty" yaml:"backendKeyPrefix,omitempty" toml:"backendKeyPrefix,omitempty"`
+	// BackendTimeoutMs bounds every redis call, in milliseconds
+	BackendTimeoutMs int64 `json:"backendTimeoutMs,omitempty" yaml:"backendTimeoutMs,omitempty" toml:"backendTimeoutMs,omitempty"`
+	// Algorithm selects the rejection logic: "fixed" (default), "sliding" or
+	// "token_bucket"
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty" toml:"algorithm,omitempty"`
+	// Burst is the token_bucket capacity. Defaults to Limit when unset.
+	Burst int32 `json:"burst,omitempty" yaml:"burst,omitempty" toml:"burst,omitempty"`
+	// IDPaths are extra JSON-pointer-style paths (e.g. "/message/from/id",
+	// "/custom_event/user/id") matched alongside the built-in telegram
+	// Update variants. Lets bot framework wrappers plug in their own
+	// envelope shape. All paths are matched in one pass over the body and
+	// the first one reached in the document's key order wins: custom paths
+	// do not take precedence over the built-in ones, and their order here
+	// has no effect.
+	IDPaths []string `json:"idPaths,omitempty" yaml:"idPaths,omitempty" toml:"idPaths,omitempty"`
+	// Metrics enables a Prometheus-format /metrics route on the management
+	// server. Console must also be enabled, since /metrics is served there.
+	Metrics bool `json:"metrics,omitempty" yaml:"metrics,omitempty" toml:"metrics,omitempty"`
+	// ConsoleAuth requires every management request to authenticate, either
+	// with a static bearer token or basic auth credentials.
+	ConsoleAuth *ConsoleAuthConfig `json:"consoleAuth,omitempty" yaml:"consoleAuth,omitempty" toml:"consoleAuth,omitempty"`
+	// ConsoleTLS serves the management server over HTTPS when set.
+	ConsoleTLS *ConsoleTLSConfig `json:"consoleTLS,omitempty" yaml:"consoleTLS,omitempty" toml:"consoleTLS,omitempty"`
+	// ReloadInterval, in seconds, periodically refreshes the whitelist and
+	// blacklist in the background. 0 (default) disables scheduled reloads.
+	ReloadInterval int64 `json:"reloadInterval,omitempty" yaml:"reloadInterval,omitempty" toml:"reloadInterval,omitempty"`
+}
+
+// ConsoleAuthConfig configures authentication for the management server.
+type ConsoleAuthConfig struct {
+	// Mode is "bearer" or "basic"
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty" toml:"mode,omitempty"`
+	// Token is the static token required for bearer mode
+	Token string `json:"token,omitempty" yaml:"token,omitempty" toml:"token,omitempty"`
+	// Username is the expected username for basic mode
+	Username string `json:"username,omitempty" yaml:"username,omitempty" toml:"username,omitempty"`
+	// Password is the expected password for basic mode
+	Password string `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty"`
+}
+
+// ConsoleTLSConfig points at a certificate/key pair for the management server.
+type ConsoleTLSConfig struct {
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty" toml:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty" toml:"keyFile,omitempty"`
 }
 
 // CreateConfig populates the Config data object
 func CreateConfig() *Config {
 	return &Config{
-		HitTableSize:   defaultHitTableSize,
-		Limit:          -1,
-		WhitelistLimit: -1,
-		Expire:         defaultExpire,
+		HitTableSize:     defaultHitTableSize,
+		Limit:            -1,
+		WhitelistLimit:   -1,
+		Expire:           defaultExpire,
+		MaxBodyBytes:     defaultMaxBodyBytes,
+		Backend:          backendMemory,
+		BackendKeyPrefix: defaultBackendKeyPrefix,
+		BackendTimeoutMs: defaultBackendTimeoutMs,
+		Algorithm:        algorithmFixed,
 	}
 }
 
@@ -85,8 +158,30 @@ type rateLimiter struct {
 	whitelist map[int64]struct{}
 	// blacklisted telegram ids
 	blacklist map[int64]struct{}
-	// hits map
-	hits *expiryMap
+	// hits store, backed by memory or a shared external store
+	hits HitStore
+	// maximum number of request body bytes read before rejecting the request
+	maxBodyBytes int64
+	// rejection algorithm: fixed, sliding or token_bucket
+	algorithm string
+	// token_bucket capacity
+	burst int32
+	// per-id state for the sliding algorithm
+	sliding *slidingMap
+	// per-id state for the token_bucket algorithm
+	tokenBucket *tokenBucketMap
+	// trie of paths checked by extractTgID
+	idPaths *idPathNode
+	// operational metrics
+	metrics *metrics
+	// conditional-GET cache for WhitelistURL / BlacklistURL, nil if unset
+	wlCache, blCache *urlCache
+	// outcome of the most recent list reload, scheduled or manual
+	reloadStatus *reloadStatus
+	// serializes updateLists, since it can run from the scheduled reload
+	// goroutine and the manual /reload route at the same time and both
+	// read/write wlCache/blCache
+	reloadMu sync.Mutex
 }
 
 // New instantiates and returns the required components used to handle a HTTP request
@@ -95,18 +190,71 @@ func New(ctx context.Context, next http.Handler, config *Config, name string) (h
 		return nil, ErrInvalidHitTableSize
 	}
 
+	maxBodyBytes := config.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+
+	hits, err := newHitStore(config)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := config.Algorithm
+	if algorithm == "" {
+		algorithm = algorithmFixed
+	}
+
+	if err := validateBackendAlgorithm(config.Backend, algorithm); err != nil {
+		return nil, err
+	}
+
+	if err := validateConsoleAuth(config.ConsoleAuth); err != nil {
+		return nil, err
+	}
+
 	r := &rateLimiter{
-		next:    next,
-		config:  config,
-		name:    name,
-		expire:  config.Expire,
-		limit:   config.Limit,
-		wlLimit: config.WhitelistLimit,
-		hits:    newExpiryMap(config.HitTableSize),
+		next:         next,
+		config:       config,
+		name:         name,
+		expire:       config.Expire,
+		limit:        config.Limit,
+		wlLimit:      config.WhitelistLimit,
+		hits:         hits,
+		maxBodyBytes: maxBodyBytes,
+		algorithm:    algorithm,
+		burst:        config.Burst,
+		idPaths:      buildIDPathTrie(config.IDPaths),
+		metrics:      newMetrics(),
+		reloadStatus: &reloadStatus{},
+	}
+	r.metrics.setHitTableCap(config.HitTableSize)
+
+	if config.WhitelistURL != nil {
+		r.wlCache = &urlCache{}
+	}
+	if config.BlacklistURL != nil {
+		r.blCache = &urlCache{}
 	}
 
+	switch algorithm {
+	case algorithmFixed, algorithmSliding, algorithmTokenBucket:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAlgorithm, algorithm)
+	}
+	// allocate every algorithm's state up front, not just the configured
+	// one, since the /limit management route can switch algorithm at
+	// runtime and must never hand rejectedSliding/rejectedTokenBucket a
+	// nil map
+	r.sliding = newSlidingMap(config.HitTableSize)
+	r.tokenBucket = newTokenBucketMap(config.HitTableSize)
+
 	r.updateLists()
 
+	if config.ReloadInterval > 0 {
+		r.startReloadLoop(ctx, time.Duration(config.ReloadInterval)*time.Second)
+	}
+
 	if config.Console {
 		err := r.startManagement(*config.ConsoleAddress)
 		if err != nil {
@@ -118,14 +266,67 @@ func New(ctx context.Context, next http.Handler, config *Config, name string) (h
 	return r, nil
 }
 
+// startReloadLoop periodically refreshes the whitelist and blacklist until
+// ctx is cancelled.
+func (r *rateLimiter) startReloadLoop(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.updateLists(); err != nil {
+					loggerError.Printf("scheduled list reload failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
 func (r *rateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	var buf bytes.Buffer
-	tee := io.TeeReader(req.Body, &buf)
-	tgID, err := extractTgID(tee)
-	req.Body = io.NopCloser(&buf)
+	req.Body = http.MaxBytesReader(rw, req.Body, r.maxBodyBytes)
+
+	spillThreshold := r.maxBodyBytes
+	if spillThreshold > defaultSpillThresholdBytes {
+		spillThreshold = defaultSpillThresholdBytes
+	}
+	sb := newSpillBuffer(spillThreshold)
+	tee := io.TeeReader(req.Body, sb)
+	extractStart := time.Now()
+	tgID, err := extractTgID(tee, r.idPaths)
+	r.metrics.extractDuration.observe(time.Since(extractStart).Seconds())
+	// drain whatever the decoder did not need so the downstream handler
+	// still sees the full body, regardless of where extraction stopped
+	if _, drainErr := io.Copy(io.Discard, tee); drainErr != nil && err == nil {
+		err = drainErr
+	}
+
+	// http.MaxBytesReader stops delivering bytes once the body exceeds
+	// maxBodyBytes: reject it outright instead of forwarding a truncated
+	// body downstream
+	var mbErr *http.MaxBytesError
+	if errors.As(err, &mbErr) {
+		sb.discard()
+		loggerInfo.Printf("rejecting oversized request body: %v", err)
+		r.metrics.observeRequest(outcomeExtractError)
+		http.Error(rw, "413 request entity too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, bodyErr := sb.body()
+	if bodyErr != nil {
+		loggerError.Printf("error buffering request body: %v", bodyErr)
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+	req.Body = body
+
 	// skip rate limiting if failed to retrieve tg ID
 	if err != nil {
 		loggerError.Printf("error retrieving telegram id: %v", err)
+		r.metrics.observeRequest(outcomeExtractError)
 		r.next.ServeHTTP(rw, req)
 		return
 	}
@@ -135,6 +336,7 @@ func (r *rateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	r.metrics.observeRequest(outcomeAllowed)
 	r.next.ServeHTTP(rw, req)
 }
 
@@ -144,55 +346,141 @@ func (r *rateLimiter) rejectedTgID(tgID int64) bool {
 	// if id is blacklisted skip handling and return 200 OK
 	if _, ok := r.blacklist[tgID]; ok {
 		loggerInfo.Printf("rejecting blacklisted id: %d", tgID)
+		r.metrics.observeRequest(outcomeRejectedBlacklist)
 		return true
 	}
 
 	_, isWl := r.whitelist[tgID]
-	hits := r.hits.incNGet(tgID, r.expire)
-
-	// if is whitelisted tg id check wlLimit
+	limit := r.limit
 	if isWl {
-		if r.wlLimit >= 0 && hits > r.wlLimit {
-			loggerInfo.Printf("rejecting whitelisted id: %d, limit: %d, hits: %d", tgID, r.wlLimit, hits)
-			return true
+		limit = r.wlLimit
+	}
+
+	var rejected bool
+	switch r.algorithm {
+	case algorithmSliding:
+		rejected = r.rejectedSliding(tgID, limit, isWl)
+	case algorithmTokenBucket:
+		rejected = r.rejectedTokenBucket(tgID, limit, isWl)
+	default:
+		rejected = r.rejectedFixed(tgID, limit, isWl)
+	}
+
+	if rejected {
+		if isWl {
+			r.metrics.observeRequest(outcomeRejectedWlLimit)
+		} else {
+			r.metrics.observeRequest(outcomeRejectedLimit)
 		}
-	} else if r.limit >= 0 && hits > r.limit {
-		loggerInfo.Printf("rejecting regular id: %d, limit: %d, hits: %d", tgID, r.limit, hits)
+	}
+
+	return rejected
+}
+
+// rejectedFixed applies the classic fixed-window counter: once an id hits
+// limit within the window it is blocked until the window fully expires.
+func (r *rateLimiter) rejectedFixed(tgID int64, limit int32, isWl bool) bool {
+	hits, err := r.hits.IncNGet(tgID, r.expire)
+	if err != nil {
+		// the hit store is unavailable: fail open rather than block traffic
+		loggerError.Printf("hit store error for id %d: %v", tgID, err)
+		return false
+	}
+
+	if limit >= 0 && hits > limit {
+		loggerInfo.Printf("rejecting id: %d, whitelisted: %t, algorithm: fixed, limit: %d, hits: %d", tgID, isWl, limit, hits)
+		return true
+	}
+
+	return false
+}
+
+// rejectedSliding applies a weighted two-window approximation of a sliding
+// window, smoothing out the bursts a fixed window allows at its boundaries.
+func (r *rateLimiter) rejectedSliding(tgID int64, limit int32, isWl bool) bool {
+	effective := r.sliding.incNGet(tgID, r.expire)
+
+	if limit >= 0 && effective > float64(limit) {
+		loggerInfo.Printf("rejecting id: %d, whitelisted: %t, algorithm: sliding, limit: %d, effective: %.2f", tgID, isWl, limit, effective)
+		return true
+	}
+
+	return false
+}
+
+// rejectedTokenBucket applies a token-bucket: burst allows short spikes,
+// while the refill rate bounds sustained throughput to limit/expire.
+func (r *rateLimiter) rejectedTokenBucket(tgID int64, limit int32, isWl bool) bool {
+	if limit < 0 {
+		return false
+	}
+
+	burst := r.burst
+	if burst <= 0 {
+		burst = limit
+	}
+
+	if !r.tokenBucket.take(tgID, r.expire, limit, burst) {
+		loggerInfo.Printf("rejecting id: %d, whitelisted: %t, algorithm: token_bucket, limit: %d, burst: %d", tgID, isWl, limit, burst)
 		return true
 	}
 
 	return false
 }
 
+// updateLists rebuilds the whitelist and blacklist from their configured
+// sources. URL sources are fetched with conditional GETs, so an unchanged
+// remote list costs a round trip rather than a full re-parse. Nothing is
+// swapped into place unless every configured source is read successfully,
+// so a failing scheduled reload leaves the current lists untouched.
+//
+// updateLists can be invoked concurrently by the scheduled reload goroutine
+// and the manual /reload route; reloadMu serializes those calls so wlCache
+// and blCache are never read and written at the same time.
 func (r *rateLimiter) updateLists() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	now := time.Now()
 	wl := make(map[int64]struct{}, 1024)
 	bl := make(map[int64]struct{}, 1024)
+
 	if r.config.Whitelist != nil {
-		err := readIDFile(*r.config.Whitelist, wl)
-		if err != nil {
+		if err := readIDFile(*r.config.Whitelist, wl); err != nil {
+			r.metrics.observeReload(reloadFailure)
+			r.reloadStatus.recordError(err, now)
 			return err
 		}
 	}
 
 	if r.config.WhitelistURL != nil {
-		err := readIDURL(*r.config.WhitelistURL, wl)
-		if err != nil {
+		if err := fetchIDURL(*r.config.WhitelistURL, r.wlCache); err != nil {
+			r.metrics.observeReload(reloadFailure)
+			r.reloadStatus.recordError(err, now)
 			return err
 		}
+		for id := range r.wlCache.ids {
+			wl[id] = struct{}{}
+		}
 	}
 
 	if r.config.Blacklist != nil {
-		err := readIDFile(*r.config.Blacklist, bl)
-		if err != nil {
+		if err := readIDFile(*r.config.Blacklist, bl); err != nil {
+			r.metrics.observeReload(reloadFailure)
+			r.reloadStatus.recordError(err, now)
 			return err
 		}
 	}
 
 	if r.config.BlacklistURL != nil {
-		err := readIDURL(*r.config.BlacklistURL, bl)
-		if err != nil {
+		if err := fetchIDURL(*r.config.BlacklistURL, r.blCache); err != nil {
+			r.metrics.observeReload(reloadFailure)
+			r.reloadStatus.recordError(err, now)
 			return err
 		}
+		for id := range r.blCache.ids {
+			bl[id] = struct{}{}
+		}
 	}
 
 	loggerInfo.Printf("updating lists. wl recs: %d, bl recs: %d", len(wl), len(bl))
@@ -200,6 +488,10 @@ func (r *rateLimiter) updateLists() error {
 	defer r.rwmu.Unlock()
 	r.whitelist = wl
 	r.blacklist = bl
+	r.metrics.observeReload(reloadSuccess)
+	r.metrics.setWhitelistSize(len(wl))
+	r.metrics.setBlacklistSize(len(bl))
+	r.reloadStatus.recordSuccess(now)
 
 	return nil
 }
@@ -210,38 +502,141 @@ func silentReject(rw http.ResponseWriter) {
 	rw.Write([]byte(http.StatusText(http.StatusOK)))
 }
 
-type tgMsg struct {
-	Message struct {
-		From struct {
-			ID *int64 `json:"id"`
-		} `json:"from"`
-	} `json:"message"`
-	CBQuery struct {
-		From struct {
-			ID *int64 `json:"id"`
-		} `json:"from"`
-	} `json:"callback_query"`
-}
+// extractTgID reads just enough of a telegram Update to find a sender id,
+// without ever buffering the whole body in memory. It walks the object
+// following idPaths, returning the first matching id found, and ignoring
+// any occurrence of the same field names nested deeper (e.g. a "from.id"
+// inside a "reply_to_message").
+func extractTgID(r io.Reader, paths *idPathNode) (int64, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
 
-func extractTgID(r io.Reader) (int64, error) {
-	body, err := io.ReadAll(r)
+	id, found, err := scanPaths(dec, paths)
 	if err != nil {
 		return 0, err
 	}
+	if !found {
+		return 0, ErrNoTelegramID
+	}
+
+	return id, nil
+}
+
+// scanPaths reads the object dec is positioned at, descending into any key
+// tracked by node and returning as soon as one of the configured paths
+// resolves to a JSON number.
+func scanPaths(dec *json.Decoder, node *idPathNode) (int64, bool, error) {
+	if err := openObject(dec); err != nil {
+		return 0, false, err
+	}
+
+	for dec.More() {
+		key, err := nextKey(dec)
+		if err != nil {
+			return 0, false, err
+		}
+
+		child, ok := node.children[key]
+		if !ok {
+			if err := skipValue(dec); err != nil {
+				return 0, false, err
+			}
+			continue
+		}
+
+		if child.leaf {
+			tok, err := dec.Token()
+			if err != nil {
+				return 0, false, err
+			}
+			if num, ok := tok.(json.Number); ok {
+				if v, err := num.Int64(); err == nil {
+					return v, true, nil
+				}
+			}
+			continue
+		}
+
+		id, found, err := scanPaths(dec, child)
+		if err != nil {
+			return 0, false, err
+		}
+		if found {
+			return id, true, nil
+		}
+	}
+
+	// the object's keys are exhausted without a match: dec.More() only
+	// reports there is nothing left, it does not consume the closing '}'.
+	// Close it here so a recursive caller's own dec.More()/nextKey calls
+	// resume at the right nesting level instead of reading this object's
+	// sibling keys as if they belonged to the child.
+	if err := closeObject(dec); err != nil {
+		return 0, false, err
+	}
+
+	return 0, false, nil
+}
 
-	var tgMsg tgMsg
-	err = json.Unmarshal(body, &tgMsg)
+// openObject consumes the opening '{' of the next JSON value.
+func openObject(dec *json.Decoder) error {
+	tok, err := dec.Token()
 	if err != nil {
-		return 0, err
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return ErrUnknownMessageFormat
+	}
+	return nil
+}
+
+// closeObject consumes the closing '}' of the current object.
+func closeObject(dec *json.Decoder) error {
+	_, err := dec.Token()
+	return err
+}
+
+// nextKey reads the next object key.
+func nextKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", ErrUnknownMessageFormat
+	}
+	return key, nil
+}
+
+// skipValue discards the next JSON value, whatever its shape.
+func skipValue(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
 	}
 
-	if tgMsg.Message.From.ID != nil {
-		return *tgMsg.Message.From.ID, nil
-	} else if tgMsg.CBQuery.From.ID != nil {
-		return *tgMsg.CBQuery.From.ID, nil
+	d, ok := tok.(json.Delim)
+	if !ok || (d != '{' && d != '[') {
+		return nil // scalar value, already consumed
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if d, ok := tok.(json.Delim); ok {
+			switch d {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
 	}
 
-	return 0, ErrUnknownMessageFormat
+	return nil
 }
 
 func readIDFile(fp string, m map[int64]struct{}) error {
@@ -256,18 +651,11 @@ func readIDFile(fp string, m map[int64]struct{}) error {
 	}
 	defer file.Close()
 
-	scanIDs(file, m)
-	return nil
-}
-
-func readIDURL(url string, m map[int64]struct{}) error {
-	res, err := http.Get(url)
+	data, err := io.ReadAll(file)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
-
-	scanIDs(res.Body, m)
+	scanIDs(bytes.NewReader(data), m)
 	return nil
 }
 
@@ -418,7 +806,18 @@ func (e *expiryMap) list() map[int64]int32 {
 }
 
 func (r *rateLimiter) startManagement(addr string) error {
-	l, err := net.Listen("tcp", addr)
+	var l net.Listener
+	var err error
+
+	if r.config.ConsoleTLS != nil {
+		cert, certErr := tls.LoadX509KeyPair(r.config.ConsoleTLS.CertFile, r.config.ConsoleTLS.KeyFile)
+		if certErr != nil {
+			return certErr
+		}
+		l, err = tls.Listen("tcp", addr, &tls.Config{Certificates: []tls.Certificate{cert}})
+	} else {
+		l, err = net.Listen("tcp", addr)
+	}
 	if err != nil {
 		return err
 	}
@@ -426,7 +825,7 @@ func (r *rateLimiter) startManagement(addr string) error {
 	go func() {
 		mux := http.NewServeMux()
 		mux.HandleFunc("/", r.serveManagement)
-		err = http.Serve(l, mux)
+		err = http.Serve(l, r.authMiddleware(mux))
 		loggerError.Printf("management server finished. error: %s", err.Error())
 	}()
 
@@ -442,9 +841,34 @@ func (r *rateLimiter) serveManagement(res http.ResponseWriter, req *http.Request
 	case n == 1 && p[0] == "reload" && req.Method == http.MethodPost:
 		r.updateLists()
 		res.WriteHeader(http.StatusNoContent)
+	case n == 2 && p[0] == "reload" && p[1] == "status" && req.Method == http.MethodGet:
+		s := r.reloadStatus.snapshot()
+		r.rwmu.RLock()
+		wlSize, blSize := len(r.whitelist), len(r.blacklist)
+		r.rwmu.RUnlock()
+		fmt.Fprintf(res, "lastSuccess %s\nlastError %s\nlastErrorAt %s\nwhitelistSize %d\nblacklistSize %d\n",
+			formatTimeOrNever(s.lastSuccess), s.lastError, formatTimeOrNever(s.lastErrorAt), wlSize, blSize)
+	case n == 1 && p[0] == "metrics" && req.Method == http.MethodGet:
+		if !r.config.Metrics {
+			http.NotFound(res, req)
+			return
+		}
+		list, err := r.hits.List()
+		if err != nil {
+			http.Error(res, "500 internal server error", http.StatusInternalServerError)
+			return
+		}
+		r.metrics.setHitTableSize(len(list))
+		res.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		r.metrics.write(res)
 	case n == 1 && p[0] == "hits" && req.Method == http.MethodGet:
+		list, err := r.hits.List()
+		if err != nil {
+			http.Error(res, "500 internal server error", http.StatusInternalServerError)
+			return
+		}
 		var data bytes.Buffer
-		for k, v := range r.hits.list() {
+		for k, v := range list {
 			data.WriteString(fmt.Sprintf("%d %d\n", k, v))
 		}
 		res.Write(data.Bytes())
@@ -456,10 +880,17 @@ func (r *rateLimiter) serveManagement(res http.ResponseWriter, req *http.Request
 		}
 		switch req.Method {
 		case http.MethodGet: // show hits
-			hits := r.hits.get(id)
+			hits, err := r.hits.Get(id)
+			if err != nil {
+				http.Error(res, "500 internal server error", http.StatusInternalServerError)
+				return
+			}
 			res.Write([]byte(strconv.Itoa(int(hits))))
 		case http.MethodDelete: // reset hits
-			r.hits.reset(id)
+			if _, err := r.hits.Reset(id); err != nil {
+				http.Error(res, "500 internal server error", http.StatusInternalServerError)
+				return
+			}
 			res.WriteHeader(http.StatusNoContent)
 		}
 	case n == 3 && p[0] == "list":
@@ -523,6 +954,27 @@ func (r *rateLimiter) serveManagement(res http.ResponseWriter, req *http.Request
 			} else {
 				r.wlLimit = int32(limit)
 			}
+			if algo := req.URL.Query().Get("algorithm"); algo != "" {
+				switch algo {
+				case algorithmFixed, algorithmSliding, algorithmTokenBucket:
+					if err := validateBackendAlgorithm(r.config.Backend, algo); err != nil {
+						http.Error(res, "400 bad request: "+err.Error(), http.StatusBadRequest)
+						return
+					}
+					r.algorithm = algo
+				default:
+					http.Error(res, "400 bad request", http.StatusBadRequest)
+					return
+				}
+			}
+			if burstStr := req.URL.Query().Get("burst"); burstStr != "" {
+				burst, err := strconv.ParseInt(burstStr, 10, 32)
+				if err != nil {
+					http.Error(res, "400 bad request", http.StatusBadRequest)
+					return
+				}
+				r.burst = int32(burst)
+			}
 			res.WriteHeader(http.StatusNoContent)
 		case http.MethodGet:
 			r.rwmu.RLock()
@@ -534,7 +986,7 @@ func (r *rateLimiter) serveManagement(res http.ResponseWriter, req *http.Request
 			} else {
 				result = r.wlLimit
 			}
-			res.Write([]byte(fmt.Sprintf("%d", result)))
+			fmt.Fprintf(res, "limit %d\nalgorithm %s\nburst %d\n", result, r.algorithm, r.burst)
 		default:
 			res.Header().Add("Allow", "GET, PUT")
 			http.Error(res, "405 method not allowed", http.StatusMethodNotAllowed)
@@ -551,3 +1003,11 @@ func parseTgID(s string) (int64, error) {
 	}
 	return id, nil
 }
+
+// formatTimeOrNever renders t in RFC3339, or "never" for the zero value.
+func formatTimeOrNever(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,134 @@
+package traefik_telegram_ratelimiter
+
+import (
+	"strings"
+	"testing"
+)
+
+func extractTgIDFromString(t *testing.T, body string) (int64, error) {
+	t.Helper()
+	return extractTgID(strings.NewReader(body), buildIDPathTrie(nil))
+}
+
+func TestExtractTgID_FieldOrder(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "from before message_id",
+			body: `{"message":{"from":{"id":42},"message_id":1}}`,
+		},
+		{
+			name: "from after message_id",
+			body: `{"message":{"message_id":1,"from":{"id":42}}}`,
+		},
+		{
+			name: "update_id precedes message entirely",
+			body: `{"update_id":7,"message":{"message_id":1,"from":{"id":42}}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id, err := extractTgIDFromString(t, c.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != 42 {
+				t.Fatalf("got id %d, want 42", id)
+			}
+		})
+	}
+}
+
+func TestExtractTgID_NestedReplyToMessageNotMatched(t *testing.T) {
+	body := `{"message":{"message_id":2,"reply_to_message":{"message_id":1,"from":{"id":999}},"from":{"id":42}}}`
+
+	id, err := extractTgIDFromString(t, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 42 {
+		t.Fatalf("got id %d, want 42 (reply_to_message.from.id must not match)", id)
+	}
+}
+
+// TestExtractTgID_FromWithoutIDDoesNotDesyncSiblingVariant covers the
+// original message/callback_query scan: if "message".from exists but
+// carries no "id", scanPaths must close that subtree before returning, or
+// the sibling "callback_query" key that follows it is read at the wrong
+// nesting level and its id is missed.
+func TestExtractTgID_FromWithoutIDDoesNotDesyncSiblingVariant(t *testing.T) {
+	body := `{"message":{"from":{"username":"no_id_here"}},"callback_query":{"from":{"id":777}}}`
+
+	id, err := extractTgIDFromString(t, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 777 {
+		t.Fatalf("got id %d, want 777", id)
+	}
+}
+
+func TestExtractTgID_ReplyToMessageOnlyHasID(t *testing.T) {
+	// No top-level from.id, only one nested inside reply_to_message: must
+	// not be picked up as a false positive.
+	body := `{"message":{"message_id":2,"reply_to_message":{"message_id":1,"from":{"id":999}}}}`
+
+	_, err := extractTgIDFromString(t, body)
+	if err != ErrNoTelegramID {
+		t.Fatalf("got err %v, want ErrNoTelegramID", err)
+	}
+}
+
+func TestExtractTgID_TruncatedJSON(t *testing.T) {
+	body := `{"message":{"from":{"i`
+
+	_, err := extractTgIDFromString(t, body)
+	if err == nil {
+		t.Fatal("expected an error for truncated JSON, got nil")
+	}
+	if err == ErrNoTelegramID {
+		t.Fatal("truncated JSON should surface a decode error, not ErrNoTelegramID")
+	}
+}
+
+func TestExtractTgID_UnknownUpdateVariant(t *testing.T) {
+	body := `{"unknown_event":{"from":{"id":42}}}`
+
+	_, err := extractTgIDFromString(t, body)
+	if err != ErrNoTelegramID {
+		t.Fatalf("got err %v, want ErrNoTelegramID", err)
+	}
+}
+
+func TestExtractTgID_CallbackQuery(t *testing.T) {
+	body := `{"callback_query":{"id":"abc","from":{"id":42}}}`
+
+	id, err := extractTgIDFromString(t, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 42 {
+		t.Fatalf("got id %d, want 42", id)
+	}
+}
+
+// TestExtractTgID_NonMatchingVariantDoesNotPoisonLaterVariant guards against
+// a decoder desync: if the first top-level variant key recurses into a
+// subtree that exhausts without reaching a leaf id (here "message" has no
+// "from"), scanPaths must still close that subtree's object so the scan
+// resumes correctly at the root and reaches a later variant key that does
+// carry the id.
+func TestExtractTgID_NonMatchingVariantDoesNotPoisonLaterVariant(t *testing.T) {
+	body := `{"message":{"chat":{"id":1}},"edited_channel_post":{"from":{"id":555}}}`
+
+	id, err := extractTgIDFromString(t, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 555 {
+		t.Fatalf("got id %d, want 555", id)
+	}
+}